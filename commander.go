@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Runner executes an external command and returns its stdout. It's the seam
+// every exec.Command call in mingle goes through, so tests can substitute a
+// mock and --debug can log every invocation in one place.
+type Runner interface {
+	Run(dir, name string, args ...string) ([]byte, error)
+}
+
+// commander is the real Runner: it shells out via exec.Command and, when a
+// logger is set, records argv, working dir, exit status, stdout/stderr and
+// duration for every command it runs.
+type commander struct {
+	logger *log.Logger
+}
+
+// defaultRunner is used by every package-level helper (getTmuxSessions,
+// createTmuxSession, ...). enableDebugLogging points it at a log file.
+var defaultRunner Runner = &commander{}
+
+func (c *commander) Run(dir, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	duration := time.Since(start)
+
+	if c.logger != nil {
+		c.logger.Printf(
+			"exec name=%s args=%v dir=%q exit=%s duration=%s stdout=%q stderr=%q",
+			name, args, dir, exitStatus(err), duration, stdout.String(), stderr.String(),
+		)
+	}
+
+	if err != nil {
+		return stdout.Bytes(), fmt.Errorf("%s %s: %w (stderr: %s)", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func exitStatus(err error) string {
+	if err == nil {
+		return "0"
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Sprintf("%d", exitErr.ExitCode())
+	}
+	return err.Error()
+}
+
+const logPath = ".config/mingle/mingle.log"
+
+// enableDebugLogging points defaultRunner at ~/.config/mingle/mingle.log, so
+// every exec.Command mingle runs (tmux, zoxide, git, tmuxinator) gets
+// recorded instead of failing silently.
+func enableDebugLogging() error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(homeDir, logPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if c, ok := defaultRunner.(*commander); ok {
+		c.logger = log.New(f, "", log.LstdFlags)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const configPath = ".config/mingle/mingle.yaml"
+
+// Config is the root of mingle.yaml. Sessions lists the statically
+// configured sessions/worktree roots, Sources controls which
+// SessionSources run and in what order, VCS configures the
+// branch-per-session source, and PickerCmd configures the interactive
+// picker `connect` falls back to when it's run without a session argument.
+type Config struct {
+	Sessions  []ConfigSession `yaml:"sessions"`
+	Sources   []string        `yaml:"sources,omitempty"`
+	VCS       *VCSConfig      `yaml:"vcs,omitempty"`
+	PickerCmd string          `yaml:"picker_cmd,omitempty"`
+}
+
+// ConfigSession is a single entry under mingle.yaml's sessions list.
+// Setting Windows opts the session into mingle's native layout builder
+// instead of tmuxinator.
+type ConfigSession struct {
+	Type          *string        `yaml:"type"`
+	Path          string         `yaml:"path"`
+	Tmuxinator    string         `yaml:"tmuxinator,omitempty"`
+	Windows       []ConfigWindow `yaml:"windows,omitempty"`
+	OnStart       []string       `yaml:"on_start,omitempty"`
+	OnStop        []string       `yaml:"on_stop,omitempty"`
+	StartupWindow string         `yaml:"startup_window,omitempty"`
+}
+
+type ConfigWindow struct {
+	Name   string       `yaml:"name"`
+	Layout string       `yaml:"layout,omitempty"`
+	Panes  []ConfigPane `yaml:"panes,omitempty"`
+}
+
+type ConfigPane struct {
+	Commands []string `yaml:"commands,omitempty"`
+	Split    string   `yaml:"split,omitempty"` // "h" or "v", ignored for a window's first pane
+	Cwd      string   `yaml:"cwd,omitempty"`
+}
+
+// VCSConfig configures the "vcs" session source: every branch under Root's
+// repositories becomes a candidate session, materialized as a git worktree
+// under WorktreeDir on first connect.
+type VCSConfig struct {
+	Root        string `yaml:"root"`
+	WorktreeDir string `yaml:"worktree_dir,omitempty"` // template, e.g. "~/worktrees/{repo}/{branch}"
+}
+
+func getConfigPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		panic(err)
+	}
+
+	return filepath.Join(homeDir, configPath)
+}
+
+func loadConfig() (*Config, error) {
+	filePath := getConfigPath()
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		fmt.Println("No config file was found")
+		return &Config{}, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	for i := range config.Sessions {
+		expandedPath, err := expandHomePath(config.Sessions[i].Path)
+		if err != nil {
+			return nil, err
+		}
+		config.Sessions[i].Path = expandedPath
+	}
+
+	if config.VCS != nil {
+		expandedRoot, err := expandHomePath(config.VCS.Root)
+		if err != nil {
+			return nil, err
+		}
+		config.VCS.Root = expandedRoot
+	}
+
+	return &config, nil
+}
+
+func expandHomePath(path string) (string, error) {
+	if strings.HasPrefix(path, "~") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(homeDir, path[1:])
+	}
+	return path, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, home, contents string) {
+	t.Helper()
+
+	dir := filepath.Join(home, ".config", "mingle")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mingle.yaml"), []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+}
+
+func TestExpandHomePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	got, err := expandHomePath("~/projects")
+	if err != nil {
+		t.Fatalf("expandHomePath returned error: %v", err)
+	}
+	if want := filepath.Join(home, "projects"); got != want {
+		t.Errorf("expandHomePath(~/projects) = %q, want %q", got, want)
+	}
+
+	got, err = expandHomePath("/abs/path")
+	if err != nil {
+		t.Fatalf("expandHomePath returned error: %v", err)
+	}
+	if got != "/abs/path" {
+		t.Errorf("expandHomePath(/abs/path) = %q, want unchanged", got)
+	}
+}
+
+func TestLoadConfig_MissingFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if len(config.Sessions) != 0 {
+		t.Errorf("expected no sessions, got %d", len(config.Sessions))
+	}
+}
+
+func TestLoadConfig_InvalidYAML(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeConfig(t, home, "sessions: [this is not valid yaml")
+
+	if _, err := loadConfig(); err == nil {
+		t.Fatal("expected an error for invalid YAML, got nil")
+	}
+}
+
+func TestLoadConfig_ExpandsHomePaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeConfig(t, home, "sessions:\n  - path: ~/code/mingle\n")
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if len(config.Sessions) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(config.Sessions))
+	}
+
+	want := filepath.Join(home, "code/mingle")
+	if config.Sessions[0].Path != want {
+		t.Errorf("Sessions[0].Path = %q, want %q", config.Sessions[0].Path, want)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Git wraps the git CLI behind a Runner, covering worktree discovery for the
+// worktreeroot config type and for the vcs session source.
+type Git struct {
+	runner Runner
+}
+
+func newGit(runner Runner) *Git {
+	return &Git{runner: runner}
+}
+
+func (g *Git) Worktrees(root string) []string {
+	output, err := g.runner.Run("", "git", "-C", root, "worktree", "list", "--porcelain")
+	if err != nil {
+		return []string{}
+	}
+
+	var worktrees []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "worktree ") {
+			worktrees = append(worktrees, strings.TrimSpace(strings.TrimPrefix(line, "worktree ")))
+		}
+	}
+
+	return worktrees
+}
+
+func (g *Git) Branches(repoPath string) []string {
+	output, err := g.runner.Run("", "git", "-C", repoPath, "branch", "--format=%(refname:short)")
+	if err != nil {
+		return nil
+	}
+
+	var branches []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			branches = append(branches, trimmed)
+		}
+	}
+
+	return branches
+}
+
+// Bookmarks lists jj bookmarks for a jj-colocated repo, the jj equivalent of
+// Branches, used by vcsSource for repos managed with jj instead of plain git.
+func (g *Git) Bookmarks(repoPath string) []string {
+	output, err := g.runner.Run("", "jj", "-R", repoPath, "bookmark", "list", "-T", `name ++ "\n"`)
+	if err != nil {
+		return nil
+	}
+
+	var bookmarks []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			bookmarks = append(bookmarks, trimmed)
+		}
+	}
+
+	return bookmarks
+}
+
+func (g *Git) AddWorktree(repoPath, path, branch string) error {
+	if _, err := g.runner.Run("", "git", "-C", repoPath, "worktree", "add", path, branch); err != nil {
+		return fmt.Errorf("error creating git worktree: %v", err)
+	}
+	return nil
+}
@@ -4,96 +4,72 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"os/user"
-	"path/filepath"
 	"strings"
 	"syscall"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 type Session struct {
-	Name       string
-	Path       string
-	Type       string
-	Tmuxinator string
+	Name          string
+	Path          string
+	Type          string
+	Tmuxinator    string
+	Windows       []ConfigWindow
+	OnStart       []string
+	OnStop        []string
+	StartupWindow string
+
+	// VCSRepoPath and VCSBranch are set on sessions produced by the vcs
+	// source, and are used by ensureVCSWorktree to create a worktree the
+	// first time the session is connected to.
+	VCSRepoPath string
+	VCSBranch   string
 }
 
-type ConfigSession struct {
-	Type       *string `yaml:"type"`
-	Path       string  `yaml:"path"`
-	Tmuxinator string  `yaml:"tmuxinator,omitempty"`
-}
-
-const configPath = ".config/mingle/mingle.yaml"
-
-func getConfigPath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		panic(err)
-	}
-
-	return filepath.Join(homeDir, configPath)
-}
-
-func loadConfig() ([]ConfigSession, error) {
-	filePath := getConfigPath()
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		fmt.Println("No config file was found")
-		return []ConfigSession{}, nil
-	}
-
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, err
-	}
-
-	var config []ConfigSession
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, err
-	}
-
-	for i := range config {
-		expandedPath, err := expandHomePath(config[i].Path)
-		if err != nil {
-			return nil, err
-		}
-		config[i].Path = expandedPath
-	}
+// tmuxClient, zoxideClient and gitClient are the package-level clients every
+// helper below uses. They're package vars, not constants, so tests can swap
+// them for a fake-Runner-backed client.
+var (
+	tmuxClient   = newTmux(defaultRunner)
+	zoxideClient = newZoxide(defaultRunner)
+	gitClient    = newGit(defaultRunner)
+	shellClient  = newShell(defaultRunner)
+)
 
-	return config, nil
+func getSessions() ([]Session, error) {
+	sessions, _, err := getSessionsWithConfig()
+	return sessions, err
 }
 
-func getSessions() ([]Session, error) {
+// getSessionsWithConfig is getSessions plus the loaded Config, for callers
+// that also need a config value (e.g. connectSessionCmd reading PickerCmd)
+// without loading mingle.yaml a second time.
+func getSessionsWithConfig() ([]Session, *Config, error) {
 	config, err := loadConfig()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	tmuxSessions := getTmuxSessions()
-	zoxideSessions := getZoxideResults()
-
-	var configSessions, configWorktreeSessions []Session
+	sessions, err := mergeSessionSources(buildSessionSources(config))
+	return sessions, config, err
+}
 
-	for _, c := range config {
-		if c.Type != nil && *c.Type == "worktreeroot" {
-			worktrees := getGitWorktrees(c.Path)
-			for _, w := range worktrees {
-				configWorktreeSessions = append(configWorktreeSessions, Session{
-					Name: w, Path: w, Type: *c.Type, Tmuxinator: c.Tmuxinator,
-				})
-			}
-		} else {
-			configSessions = append(configSessions, Session{
-				Name:       c.Path,
-				Path:       c.Path,
-				Type:       "",
-				Tmuxinator: c.Tmuxinator,
-			})
-		}
+// sessionNames extracts Session.Name from every session, in order. Shared by
+// connectSessionCmd's ValidArgsFunction and pickSession so shell completion
+// and the interactive picker always offer the same names.
+func sessionNames(sessions []Session) []string {
+	names := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		names = append(names, s.Name)
 	}
+	return names
+}
 
+// mergeSessionSources concatenates every source's sessions in order,
+// dropping later sessions that collide by name (after dots are rewritten to
+// underscores, since tmux treats session names with dots specially).
+func mergeSessionSources(sources []SessionSource) ([]Session, error) {
 	var sessions []Session
 	sessionNames := make(map[string]struct{})
 
@@ -106,79 +82,33 @@ func getSessions() ([]Session, error) {
 		}
 	}
 
-	for _, s := range tmuxSessions {
-		addSession(s)
-	}
-	for _, s := range configSessions {
-		addSession(s)
-	}
-	for _, s := range configWorktreeSessions {
-		addSession(s)
-	}
-	for _, s := range zoxideSessions {
-		addSession(s)
+	for _, source := range sources {
+		list, err := source.List()
+		if err != nil {
+			return nil, fmt.Errorf("error listing sessions from %s source: %v", source.Name(), err)
+		}
+		for _, s := range list {
+			addSession(s)
+		}
 	}
 
 	return sessions, nil
 }
 
 func getTmuxSessions() []Session {
-	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}")
-	output, err := cmd.Output()
-	if err != nil {
-		return []Session{}
-	}
-
-	lines := strings.Split(string(output), "\n")
-	var sessions []Session
-	for _, line := range lines {
-		if trimmed := strings.TrimSpace(line); trimmed != "" {
-			sessions = append(sessions, Session{Name: trimmed})
-		}
-	}
-
-	return sessions
+	return tmuxClient.ListSessions()
 }
 
 func getZoxideResults() []Session {
-	cmd := exec.Command("zoxide", "query", "-l")
-	output, err := cmd.Output()
-	if err != nil {
-		return []Session{}
-	}
-
-	lines := strings.Split(string(output), "\n")
-	var results []Session
-	for _, line := range lines {
-		if trimmed := strings.TrimSpace(line); trimmed != "" {
-			results = append(results, Session{Name: trimmed, Path: trimmed})
-		}
-	}
-
-	return results
+	return zoxideClient.Query()
 }
 
 func getGitWorktrees(worktreeRoot string) []string {
-	cmd := exec.Command("git", "-C", worktreeRoot, "worktree", "list", "--porcelain")
-	output, err := cmd.Output()
-	if err != nil {
-		return []string{}
-	}
-
-	lines := strings.Split(string(output), "\n")
-	var worktrees []string
-	for _, line := range lines {
-		if strings.HasPrefix(line, "worktree ") {
-			worktrees = append(worktrees, strings.TrimSpace(strings.TrimPrefix(line, "worktree ")))
-		}
-	}
-
-	return worktrees
+	return gitClient.Worktrees(worktreeRoot)
 }
 
 func switchToTmuxSession(sessionName string) error {
-	cmd := exec.Command("tmux", "switch-client", "-t", sessionName)
-	if err := cmd.Run(); err != nil {
+	if err := tmuxClient.SwitchClient(sessionName); err != nil {
 		return fmt.Errorf("error switching to tmux session: %v", err)
 	}
 	return nil
@@ -189,16 +119,16 @@ func createTmuxSession(session Session) error {
 		return fmt.Errorf("session path is missing, cannot create session")
 	}
 
-	if session.Tmuxinator != "" {
-		cmd := exec.Command("sh", "-c",
-			fmt.Sprintf("cd %s && yes | tmuxinator start -n %s -p %s --no-attach", session.Path, session.Name, session.Tmuxinator),
-		)
-		if err := cmd.Run(); err != nil {
+	switch {
+	case len(session.Windows) > 0:
+		return createNativeTmuxSession(session)
+	case session.Tmuxinator != "":
+		command := fmt.Sprintf("yes | tmuxinator start -n %s -p %s --no-attach", session.Name, session.Tmuxinator)
+		if err := shellClient.Run(session.Path, command); err != nil {
 			return fmt.Errorf("error starting tmuxinator session: %v", err)
 		}
-	} else {
-		cmd := exec.Command("tmux", "new-session", "-s", session.Name, "-d", "-c", session.Path)
-		if err := cmd.Run(); err != nil {
+	default:
+		if err := tmuxClient.NewSession(session.Name, session.Path); err != nil {
 			return fmt.Errorf("error creating new tmux session: %v", err)
 		}
 	}
@@ -206,6 +136,80 @@ func createTmuxSession(session Session) error {
 	return nil
 }
 
+// createNativeTmuxSession builds a session from a structured Windows layout,
+// without depending on tmuxinator. It runs session-level on_start commands,
+// creates one tmux window per configured window, splits panes inside each
+// window, sends each pane's startup commands, and applies the requested
+// layout before optionally focusing a startup window.
+func createNativeTmuxSession(session Session) error {
+	for _, c := range session.OnStart {
+		if err := shellClient.Run(session.Path, c); err != nil {
+			return fmt.Errorf("error running on_start command %q: %v", c, err)
+		}
+	}
+
+	for i, window := range session.Windows {
+		windowTarget := fmt.Sprintf("%s:%s", session.Name, window.Name)
+
+		if i == 0 {
+			args := []string{"new-session", "-d", "-s", session.Name, "-c", session.Path}
+			if window.Name != "" {
+				args = append(args, "-n", window.Name)
+			}
+			if err := tmuxClient.Run(args...); err != nil {
+				return fmt.Errorf("error creating new tmux session: %v", err)
+			}
+		} else {
+			args := []string{"new-window", "-t", session.Name, "-c", session.Path}
+			if window.Name != "" {
+				args = append(args, "-n", window.Name)
+			}
+			if err := tmuxClient.Run(args...); err != nil {
+				return fmt.Errorf("error creating window %q: %v", window.Name, err)
+			}
+		}
+
+		for j, pane := range window.Panes {
+			paneTarget := windowTarget
+			if j > 0 {
+				splitFlag := "-v"
+				if pane.Split == "h" {
+					splitFlag = "-h"
+				}
+				args := []string{"split-window", splitFlag, "-t", windowTarget}
+				if pane.Cwd != "" {
+					args = append(args, "-c", pane.Cwd)
+				} else {
+					args = append(args, "-c", session.Path)
+				}
+				if err := tmuxClient.Run(args...); err != nil {
+					return fmt.Errorf("error splitting pane in window %q: %v", window.Name, err)
+				}
+			}
+
+			for _, c := range pane.Commands {
+				if err := tmuxClient.Run("send-keys", "-t", paneTarget, c, "Enter"); err != nil {
+					return fmt.Errorf("error sending command to pane in window %q: %v", window.Name, err)
+				}
+			}
+		}
+
+		if window.Layout != "" {
+			if err := tmuxClient.Run("select-layout", "-t", windowTarget, window.Layout); err != nil {
+				return fmt.Errorf("error applying layout to window %q: %v", window.Name, err)
+			}
+		}
+	}
+
+	if session.StartupWindow != "" {
+		if err := tmuxClient.Run("select-window", "-t", fmt.Sprintf("%s:%s", session.Name, session.StartupWindow)); err != nil {
+			return fmt.Errorf("error selecting startup window: %v", err)
+		}
+	}
+
+	return nil
+}
+
 func listSessionsCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
@@ -229,17 +233,34 @@ func connectSessionCmd() *cobra.Command {
 		Use:   "connect <session>",
 		Short: "Connect to a given session",
 		Args:  cobra.MaximumNArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				return nil
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
 			}
 
-			sessionName := args[0]
 			sessions, err := getSessions()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveError
+			}
+
+			return sessionNames(sessions), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessions, config, err := getSessionsWithConfig()
 			if err != nil {
 				return err
 			}
 
+			sessionName := ""
+			if len(args) == 0 {
+				sessionName, err = pickSession(sessions, config.PickerCmd)
+				if err != nil {
+					return err
+				}
+			} else {
+				sessionName = args[0]
+			}
+
 			var selectedSession *Session
 			for _, s := range sessions {
 				if s.Name == sessionName {
@@ -252,6 +273,12 @@ func connectSessionCmd() *cobra.Command {
 				return fmt.Errorf("session %s not found", sessionName)
 			}
 
+			if selectedSession.Type == "vcs" {
+				if err := ensureVCSWorktree(*selectedSession); err != nil {
+					return err
+				}
+			}
+
 			tmuxSessions := getTmuxSessions()
 
 			sessionExists := false
@@ -276,7 +303,7 @@ func connectSessionCmd() *cobra.Command {
 					}
 				}
 
-				tmuxPath, err := exec.LookPath("tmux")
+				tmuxPath, err := lookPath("tmux")
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Error finding tmux: %v\n", err)
 					os.Exit(1)
@@ -286,7 +313,7 @@ func connectSessionCmd() *cobra.Command {
 				env := os.Environ()
 
 				// Replace the current process with the tmux command
-				if err := syscall.Exec(tmuxPath, args, env); err != nil {
+				if err := execSyscall(tmuxPath, args, env); err != nil {
 					fmt.Fprintf(os.Stderr, "Error executing tmux: %v\n", err)
 					os.Exit(1)
 				}
@@ -297,29 +324,168 @@ func connectSessionCmd() *cobra.Command {
 	}
 }
 
-func expandHomePath(path string) (string, error) {
-	if strings.HasPrefix(path, "~") {
-		usr, err := user.Current()
-		if err != nil {
-			return "", err
+func stopSessionCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "stop [session]",
+		Short: "Stop a running session",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all {
+				return stopAllSessions()
+			}
+
+			if len(args) == 0 {
+				return fmt.Errorf("specify a session name or pass --all")
+			}
+
+			return stopSession(args[0])
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Stop all running tmux sessions")
+
+	return cmd
+}
+
+func stopAllSessions() error {
+	for _, s := range getTmuxSessions() {
+		if err := stopSession(s.Name); err != nil {
+			return err
 		}
-		path = filepath.Join(usr.HomeDir, path[1:])
 	}
-	return path, nil
+	return nil
+}
+
+func stopSession(name string) error {
+	running := getTmuxSessions()
+
+	isRunning := false
+	for _, s := range running {
+		if s.Name == name {
+			isRunning = true
+			break
+		}
+	}
+	if !isRunning {
+		return fmt.Errorf("session %s is not running", name)
+	}
+
+	if isInsideTmuxSession() {
+		current, err := getCurrentTmuxSession()
+		if err == nil && current == name {
+			for _, s := range running {
+				if s.Name != name {
+					// Switching first is just a convenience so the user isn't
+					// dropped out of tmux; a failure here shouldn't stop us
+					// from killing the session below, which is the actual
+					// point of this command.
+					if err := switchToTmuxSession(s.Name); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: failed to switch to session %q before stopping %q: %v\n", s.Name, name, err)
+					}
+					break
+				}
+			}
+		}
+	}
+
+	session, found, err := lookupOnStopSession(name)
+	if err != nil {
+		return err
+	}
+	if found {
+		for _, c := range session.OnStop {
+			if err := shellClient.Run(session.Path, c); err != nil {
+				return fmt.Errorf("error running on_stop command %q: %v", c, err)
+			}
+		}
+	}
+
+	if err := tmuxClient.KillSession(name); err != nil {
+		return fmt.Errorf("error killing tmux session: %v", err)
+	}
+
+	return nil
+}
+
+// lookupOnStopSession finds the named session's on_stop hooks without
+// re-running the full (tmux/zoxide/vcs) source pipeline: on_stop is only
+// ever set from mingle.yaml, on plain config entries and the worktrees
+// derived from worktreeroot entries, so only those two sources are needed
+// here -- and only the ones the user's configured `sources:` actually
+// enables, so a session excluded from discovery doesn't pick up hooks here.
+func lookupOnStopSession(name string) (Session, bool, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return Session{}, false, err
+	}
+
+	order := config.Sources
+	if len(order) == 0 {
+		order = defaultSourceOrder
+	}
+
+	var sources []SessionSource
+	for _, n := range order {
+		switch n {
+		case "config":
+			sources = append(sources, configSource{sessions: config.Sessions})
+		case "worktrees":
+			sources = append(sources, worktreeSource{sessions: config.Sessions})
+		}
+	}
+
+	sessions, err := mergeSessionSources(sources)
+	if err != nil {
+		return Session{}, false, err
+	}
+
+	for _, s := range sessions {
+		if s.Name == name {
+			return s, true, nil
+		}
+	}
+
+	return Session{}, false, nil
+}
+
+func getCurrentTmuxSession() (string, error) {
+	return tmuxClient.CurrentSession()
 }
 
 func isInsideTmuxSession() bool {
 	return os.Getenv("TMUX") != ""
 }
 
+// lookPath and execSyscall are seams over exec.LookPath and syscall.Exec, so
+// connectSessionCmd's outside-tmux branch can be exercised in tests without
+// actually replacing the test process.
+var (
+	lookPath    = exec.LookPath
+	execSyscall = syscall.Exec
+)
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "mingle",
 		Short: "Tool to improve my workflow by mingling other tools together",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			debug, _ := cmd.Flags().GetBool("debug")
+			if debug || os.Getenv("MINGLE_DEBUG") != "" {
+				if err := enableDebugLogging(); err != nil {
+					return fmt.Errorf("error enabling debug logging: %v", err)
+				}
+			}
+			return nil
+		},
 	}
 
+	rootCmd.PersistentFlags().Bool("debug", false, "Log every executed command to ~/.config/mingle/mingle.log")
+
 	rootCmd.AddCommand(listSessionsCmd())
 	rootCmd.AddCommand(connectSessionCmd())
+	rootCmd.AddCommand(stopSessionCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
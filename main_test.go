@@ -0,0 +1,352 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type fakeSource struct {
+	name     string
+	sessions []Session
+	err      error
+}
+
+func (f fakeSource) Name() string             { return f.name }
+func (f fakeSource) List() ([]Session, error) { return f.sessions, f.err }
+
+func TestMergeSessionSources_DedupAndDotRewrite(t *testing.T) {
+	sources := []SessionSource{
+		fakeSource{name: "tmux", sessions: []Session{{Name: "my.project"}}},
+		fakeSource{name: "config", sessions: []Session{
+			{Name: "my.project", Path: "/should/be/dropped"},
+			{Name: "other", Path: "/other"},
+		}},
+	}
+
+	sessions, err := mergeSessionSources(sources)
+	if err != nil {
+		t.Fatalf("mergeSessionSources returned error: %v", err)
+	}
+
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions after dedup, got %d: %+v", len(sessions), sessions)
+	}
+	if sessions[0].Name != "my_project" {
+		t.Errorf("Sessions[0].Name = %q, want my_project", sessions[0].Name)
+	}
+	if sessions[0].Path != "" {
+		t.Errorf("expected the first-seen session to win, got Path %q", sessions[0].Path)
+	}
+	if sessions[1].Name != "other" {
+		t.Errorf("Sessions[1].Name = %q, want other", sessions[1].Name)
+	}
+}
+
+func TestMergeSessionSources_PropagatesSourceError(t *testing.T) {
+	sources := []SessionSource{
+		fakeSource{name: "tmux", err: errors.New("boom")},
+	}
+
+	if _, err := mergeSessionSources(sources); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
+
+func withFakeClients(t *testing.T, fake Runner) {
+	t.Helper()
+
+	originalTmux, originalZoxide, originalGit, originalShell := tmuxClient, zoxideClient, gitClient, shellClient
+	tmuxClient, zoxideClient, gitClient, shellClient = newTmux(fake), newZoxide(fake), newGit(fake), newShell(fake)
+	t.Cleanup(func() {
+		tmuxClient, zoxideClient, gitClient, shellClient = originalTmux, originalZoxide, originalGit, originalShell
+	})
+}
+
+func TestConnectSessionCmd_InsideTmux_SwitchesClient(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,12345,0")
+	writeConfig(t, home, "sessions:\n  - path: /work/project\n")
+
+	fake := &fakeRunner{}
+	withFakeClients(t, fake)
+
+	cmd := connectSessionCmd()
+	cmd.SetArgs([]string{"/work/project"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("connect returned error: %v", err)
+	}
+
+	wantCreate := "tmux new-session -s /work/project -d -c /work/project"
+	wantSwitch := "tmux switch-client -t /work/project"
+	if !containsCall(fake.calls, wantCreate) {
+		t.Errorf("expected call %q, got %v", wantCreate, fake.calls)
+	}
+	if !containsCall(fake.calls, wantSwitch) {
+		t.Errorf("expected call %q, got %v", wantSwitch, fake.calls)
+	}
+}
+
+func TestConnectSessionCmd_OutsideTmux_ExecsAttach(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TMUX", "")
+	writeConfig(t, home, "sessions:\n  - path: /work/project\n")
+
+	fake := &fakeRunner{}
+	withFakeClients(t, fake)
+
+	originalLookPath, originalExecSyscall := lookPath, execSyscall
+	var execedPath string
+	var execedArgs []string
+	lookPath = func(file string) (string, error) { return "/usr/bin/tmux", nil }
+	execSyscall = func(argv0 string, argv []string, envv []string) error {
+		execedPath = argv0
+		execedArgs = argv
+		return nil
+	}
+	t.Cleanup(func() { lookPath, execSyscall = originalLookPath, originalExecSyscall })
+
+	cmd := connectSessionCmd()
+	cmd.SetArgs([]string{"/work/project"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("connect returned error: %v", err)
+	}
+
+	if execedPath != "/usr/bin/tmux" {
+		t.Errorf("execSyscall argv0 = %q, want /usr/bin/tmux", execedPath)
+	}
+	want := []string{"tmux", "attach-session", "-t", "/work/project"}
+	if len(execedArgs) != len(want) {
+		t.Fatalf("execSyscall args = %v, want %v", execedArgs, want)
+	}
+	for i := range want {
+		if execedArgs[i] != want[i] {
+			t.Fatalf("execSyscall args = %v, want %v", execedArgs, want)
+		}
+	}
+}
+
+func TestConnectSessionCmd_ValidArgsFunction_ListsSessionNames(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeConfig(t, home, "sessions:\n  - path: /work/project\n")
+
+	fake := &fakeRunner{}
+	withFakeClients(t, fake)
+
+	cmd := connectSessionCmd()
+	names, directive := cmd.ValidArgsFunction(cmd, nil, "")
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("directive = %v, want ShellCompDirectiveNoFileComp", directive)
+	}
+	if !containsCall(names, "/work/project") {
+		t.Errorf("expected completions %v to contain /work/project", names)
+	}
+}
+
+func TestConnectSessionCmd_NoArgs_UsesPicker(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,12345,0")
+	writeConfig(t, home, "sessions:\n  - path: /work/project\npicker_cmd: sk\n")
+
+	fake := &fakeRunner{}
+	withFakeClients(t, fake)
+
+	var gotPickerCmd string
+	withFakePicker(t, func(pickerCmd, stdin string) (string, error) {
+		gotPickerCmd = pickerCmd
+		return "/work/project", nil
+	})
+
+	cmd := connectSessionCmd()
+	cmd.SetArgs(nil)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("connect returned error: %v", err)
+	}
+
+	if gotPickerCmd != "sk" {
+		t.Errorf("picker_cmd = %q, want sk", gotPickerCmd)
+	}
+
+	wantSwitch := "tmux switch-client -t /work/project"
+	if !containsCall(fake.calls, wantSwitch) {
+		t.Errorf("expected call %q, got %v", wantSwitch, fake.calls)
+	}
+}
+
+func TestStopSession_RunsOnStopForPlainSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TMUX", "")
+	writeConfig(t, home, "sources: [config, tmux, zoxide]\nsessions:\n  - path: /work/project\n    on_stop:\n      - \"make stop\"\n")
+
+	fake := &fakeRunner{
+		outputs: map[string][]byte{
+			"tmux list-sessions -F #{session_name}": []byte("/work/project\n"),
+		},
+	}
+	withFakeClients(t, fake)
+
+	if err := stopSession("/work/project"); err != nil {
+		t.Fatalf("stopSession returned error: %v", err)
+	}
+
+	wantStop := "sh -c make stop"
+	if !containsCall(fake.calls, wantStop) {
+		t.Errorf("expected call %q, got %v", wantStop, fake.calls)
+	}
+}
+
+func TestStopSession_KillsSessionEvenWhenSwitchClientFails(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,12345,0")
+	writeConfig(t, home, "sessions:\n  - path: /work/project\n")
+
+	fake := &fakeRunner{
+		outputs: map[string][]byte{
+			"tmux list-sessions -F #{session_name}": []byte("/work/project\nother\n"),
+			"tmux display-message -p #S":            []byte("/work/project\n"),
+		},
+		errs: map[string]error{
+			"tmux switch-client -t other": errors.New("no current client"),
+		},
+	}
+	withFakeClients(t, fake)
+
+	if err := stopSession("/work/project"); err != nil {
+		t.Fatalf("stopSession returned error: %v, want it to fall through to kill-session despite the switch-client failure", err)
+	}
+
+	wantKill := "tmux kill-session -t /work/project"
+	if !containsCall(fake.calls, wantKill) {
+		t.Errorf("expected call %q, got %v", wantKill, fake.calls)
+	}
+}
+
+func TestStopSession_OnStopLookupIgnoresBrokenVCSSource(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TMUX", "")
+	writeConfig(t, home, "sources: [vcs, config, tmux]\nvcs:\n  root: /nonexistent/vcs/root\nsessions:\n  - path: /work/project\n    on_stop:\n      - \"make stop\"\n")
+
+	fake := &fakeRunner{
+		outputs: map[string][]byte{
+			"tmux list-sessions -F #{session_name}": []byte("/work/project\n"),
+		},
+	}
+	withFakeClients(t, fake)
+
+	if err := stopSession("/work/project"); err != nil {
+		t.Fatalf("stopSession returned error: %v, want the on_stop lookup to ignore the broken vcs source", err)
+	}
+
+	wantStop := "sh -c make stop"
+	if !containsCall(fake.calls, wantStop) {
+		t.Errorf("expected call %q, got %v", wantStop, fake.calls)
+	}
+}
+
+func TestStopSession_OnStopLookupRespectsSourcesConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TMUX", "")
+	writeConfig(t, home, "sources: [tmux]\nsessions:\n  - path: /work/project\n    on_stop:\n      - \"make stop\"\n")
+
+	fake := &fakeRunner{
+		outputs: map[string][]byte{
+			"tmux list-sessions -F #{session_name}": []byte("/work/project\n"),
+		},
+	}
+	withFakeClients(t, fake)
+
+	if err := stopSession("/work/project"); err != nil {
+		t.Fatalf("stopSession returned error: %v", err)
+	}
+
+	wantStop := "sh -c make stop"
+	if containsCall(fake.calls, wantStop) {
+		t.Errorf("expected on_stop to be skipped since sources: [tmux] excludes config, but got call %q in %v", wantStop, fake.calls)
+	}
+}
+
+func TestCreateNativeTmuxSession_MultiWindowMultiPane(t *testing.T) {
+	fake := &fakeRunner{}
+	withFakeClients(t, fake)
+
+	session := Session{
+		Name:          "proj",
+		Path:          "/work/proj",
+		OnStart:       []string{"echo start"},
+		StartupWindow: "editor",
+		Windows: []ConfigWindow{
+			{
+				Name:   "editor",
+				Layout: "main-vertical",
+				Panes: []ConfigPane{
+					{Commands: []string{"nvim ."}},
+					{Commands: []string{"npm run dev"}, Split: "h"},
+				},
+			},
+			{
+				Name: "server",
+				Panes: []ConfigPane{
+					{Commands: []string{"make run"}},
+				},
+			},
+		},
+	}
+
+	if err := createNativeTmuxSession(session); err != nil {
+		t.Fatalf("createNativeTmuxSession returned error: %v", err)
+	}
+
+	want := []string{
+		"sh -c echo start",
+		"tmux new-session -d -s proj -c /work/proj -n editor",
+		"tmux send-keys -t proj:editor nvim . Enter",
+		"tmux split-window -h -t proj:editor -c /work/proj",
+		"tmux send-keys -t proj:editor npm run dev Enter",
+		"tmux select-layout -t proj:editor main-vertical",
+		"tmux new-window -t proj -c /work/proj -n server",
+		"tmux send-keys -t proj:server make run Enter",
+		"tmux select-window -t proj:editor",
+	}
+
+	if len(fake.calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", fake.calls, want)
+	}
+	for i := range want {
+		if fake.calls[i] != want[i] {
+			t.Errorf("call %d = %q, want %q", i, fake.calls[i], want[i])
+		}
+	}
+}
+
+func TestCreateTmuxSession_Tmuxinator_RoutesThroughShellClient(t *testing.T) {
+	fake := &fakeRunner{}
+	withFakeClients(t, fake)
+
+	session := Session{Name: "proj", Path: "/work/proj", Tmuxinator: "proj"}
+	if err := createTmuxSession(session); err != nil {
+		t.Fatalf("createTmuxSession returned error: %v", err)
+	}
+
+	want := "sh -c yes | tmuxinator start -n proj -p proj --no-attach"
+	if !containsCall(fake.calls, want) {
+		t.Errorf("expected call %q, got %v", want, fake.calls)
+	}
+}
+
+func containsCall(calls []string, want string) bool {
+	for _, c := range calls {
+		if c == want {
+			return true
+		}
+	}
+	return false
+}
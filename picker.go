@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultPickerCmd is used when mingle.yaml doesn't set `picker_cmd`.
+const defaultPickerCmd = "fzf"
+
+// runPicker shells out to pickerCmd, feeding it stdin on its standard input
+// and returning whatever it writes to standard output. It's a seam over
+// exec.Command, not the Runner used for tmux/zoxide/git: fzf-style pickers
+// open /dev/tty directly for their UI, so stdin/stdout here are plain data
+// pipes rather than something a fake process needs to interpret.
+var runPicker = func(pickerCmd, stdin string) (string, error) {
+	fields := strings.Fields(pickerCmd)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("picker_cmd is empty")
+	}
+
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stderr = os.Stderr
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w", pickerCmd, err)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// pickSession runs pickerCmd (or defaultPickerCmd, if unset) with every
+// session name on stdin and returns the one the user picked. connectSessionCmd
+// uses it when invoked with no session argument, instead of the old
+// behaviour of silently doing nothing.
+func pickSession(sessions []Session, pickerCmd string) (string, error) {
+	if pickerCmd == "" {
+		pickerCmd = defaultPickerCmd
+	}
+
+	selected, err := runPicker(pickerCmd, strings.Join(sessionNames(sessions), "\n"))
+	if err != nil {
+		return "", fmt.Errorf("error running picker: %v", err)
+	}
+	if selected == "" {
+		return "", fmt.Errorf("no session selected")
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func withFakePicker(t *testing.T, fake func(pickerCmd, stdin string) (string, error)) {
+	t.Helper()
+
+	original := runPicker
+	runPicker = fake
+	t.Cleanup(func() { runPicker = original })
+}
+
+func TestPickSession_PassesNamesAndPickerCmd(t *testing.T) {
+	var gotCmd, gotStdin string
+	withFakePicker(t, func(pickerCmd, stdin string) (string, error) {
+		gotCmd, gotStdin = pickerCmd, stdin
+		return "my_project", nil
+	})
+
+	sessions := []Session{{Name: "my_project"}, {Name: "other"}}
+	got, err := pickSession(sessions, "sk")
+	if err != nil {
+		t.Fatalf("pickSession returned error: %v", err)
+	}
+	if got != "my_project" {
+		t.Errorf("pickSession = %q, want my_project", got)
+	}
+	if gotCmd != "sk" {
+		t.Errorf("runPicker pickerCmd = %q, want sk", gotCmd)
+	}
+	if gotStdin != "my_project\nother" {
+		t.Errorf("runPicker stdin = %q, want %q", gotStdin, "my_project\nother")
+	}
+}
+
+func TestPickSession_DefaultsToFzf(t *testing.T) {
+	var gotCmd string
+	withFakePicker(t, func(pickerCmd, stdin string) (string, error) {
+		gotCmd = pickerCmd
+		return "a", nil
+	})
+
+	if _, err := pickSession([]Session{{Name: "a"}}, ""); err != nil {
+		t.Fatalf("pickSession returned error: %v", err)
+	}
+	if gotCmd != defaultPickerCmd {
+		t.Errorf("runPicker pickerCmd = %q, want %q", gotCmd, defaultPickerCmd)
+	}
+}
+
+func TestPickSession_PropagatesPickerError(t *testing.T) {
+	withFakePicker(t, func(pickerCmd, stdin string) (string, error) {
+		return "", errors.New("boom")
+	})
+
+	if _, err := pickSession([]Session{{Name: "a"}}, ""); err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+}
+
+func TestPickSession_EmptySelectionIsError(t *testing.T) {
+	withFakePicker(t, func(pickerCmd, stdin string) (string, error) {
+		return "", nil
+	})
+
+	if _, err := pickSession([]Session{{Name: "a"}}, ""); err == nil {
+		t.Fatal("expected an error for an empty selection, got nil")
+	}
+}
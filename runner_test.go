@@ -0,0 +1,25 @@
+package main
+
+import "strings"
+
+// fakeRunner is a Runner that returns canned output per "name args..." key
+// instead of shelling out, so tmux/zoxide/git clients can be tested without
+// the real binaries.
+type fakeRunner struct {
+	outputs map[string][]byte
+	errs    map[string]error
+	calls   []string
+}
+
+func (f *fakeRunner) Run(dir, name string, args ...string) ([]byte, error) {
+	key := name + " " + strings.Join(args, " ")
+	f.calls = append(f.calls, key)
+
+	if f.errs != nil {
+		if err, ok := f.errs[key]; ok {
+			return nil, err
+		}
+	}
+
+	return f.outputs[key], nil
+}
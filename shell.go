@@ -0,0 +1,17 @@
+package main
+
+// Shell wraps arbitrary "sh -c" invocations behind a Runner, used for
+// tmuxinator startup and session on_start/on_stop hooks so they go through
+// the same seam (and --debug logging) as the typed tmux/zoxide/git clients.
+type Shell struct {
+	runner Runner
+}
+
+func newShell(runner Runner) *Shell {
+	return &Shell{runner: runner}
+}
+
+func (s *Shell) Run(dir, command string) error {
+	_, err := s.runner.Run(dir, "sh", "-c", command)
+	return err
+}
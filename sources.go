@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SessionSource produces a list of candidate sessions from one provider
+// (tmux, zoxide, static config paths, git worktrees, ...). getSessions
+// merges the output of every configured source, in the configured order,
+// first-seen-wins on name collisions.
+type SessionSource interface {
+	Name() string
+	List() ([]Session, error)
+}
+
+// defaultSourceOrder is used when mingle.yaml doesn't set `sources`, and
+// matches mingle's original, hardcoded merge order.
+var defaultSourceOrder = []string{"tmux", "config", "worktrees", "zoxide"}
+
+func buildSessionSources(config *Config) []SessionSource {
+	order := config.Sources
+	if len(order) == 0 {
+		order = defaultSourceOrder
+	}
+
+	var sources []SessionSource
+	for _, name := range order {
+		switch name {
+		case "tmux":
+			sources = append(sources, tmuxSource{})
+		case "zoxide":
+			sources = append(sources, zoxideSource{})
+		case "config":
+			sources = append(sources, configSource{sessions: config.Sessions})
+		case "worktrees":
+			sources = append(sources, worktreeSource{sessions: config.Sessions})
+		case "vcs":
+			if config.VCS != nil {
+				sources = append(sources, vcsSource{config: *config.VCS})
+			}
+		}
+	}
+
+	return sources
+}
+
+type tmuxSource struct{}
+
+func (tmuxSource) Name() string { return "tmux" }
+
+func (tmuxSource) List() ([]Session, error) {
+	return getTmuxSessions(), nil
+}
+
+type zoxideSource struct{}
+
+func (zoxideSource) Name() string { return "zoxide" }
+
+func (zoxideSource) List() ([]Session, error) {
+	return getZoxideResults(), nil
+}
+
+// configSource turns plain (non-worktreeroot) entries in mingle.yaml into
+// sessions, one per configured path.
+type configSource struct {
+	sessions []ConfigSession
+}
+
+func (configSource) Name() string { return "config" }
+
+func (s configSource) List() ([]Session, error) {
+	var sessions []Session
+	for _, c := range s.sessions {
+		if c.Type != nil && *c.Type == "worktreeroot" {
+			continue
+		}
+		sessions = append(sessions, Session{
+			Name:          c.Path,
+			Path:          c.Path,
+			Tmuxinator:    c.Tmuxinator,
+			Windows:       c.Windows,
+			OnStart:       c.OnStart,
+			OnStop:        c.OnStop,
+			StartupWindow: c.StartupWindow,
+		})
+	}
+	return sessions, nil
+}
+
+// worktreeSource expands `type: worktreeroot` entries in mingle.yaml into
+// one session per git worktree found under that root.
+type worktreeSource struct {
+	sessions []ConfigSession
+}
+
+func (worktreeSource) Name() string { return "worktrees" }
+
+func (s worktreeSource) List() ([]Session, error) {
+	var sessions []Session
+	for _, c := range s.sessions {
+		if c.Type == nil || *c.Type != "worktreeroot" {
+			continue
+		}
+		for _, w := range gitClient.Worktrees(c.Path) {
+			sessions = append(sessions, Session{
+				Name: w, Path: w, Type: *c.Type, Tmuxinator: c.Tmuxinator, OnStop: c.OnStop,
+			})
+		}
+	}
+	return sessions, nil
+}
+
+// vcsSource discovers per-branch sessions under a configured VCS root,
+// inspired by tmux-vcs-sync: every branch (or jj bookmark) under a repo
+// becomes a candidate session named "<repo>/<branch>", materialized as a
+// git worktree on first connect by ensureVCSWorktree.
+type vcsSource struct {
+	config VCSConfig
+}
+
+func (vcsSource) Name() string { return "vcs" }
+
+func (s vcsSource) List() ([]Session, error) {
+	if s.config.Root == "" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(s.config.Root)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading vcs root %q: %v\n", s.config.Root, err)
+		return nil, nil
+	}
+
+	var sessions []Session
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		repo := entry.Name()
+		repoPath := filepath.Join(s.config.Root, repo)
+
+		for _, branch := range s.workUnits(repoPath) {
+			sessions = append(sessions, Session{
+				Name:        fmt.Sprintf("%s/%s", repo, branch),
+				Path:        s.worktreePath(repo, branch),
+				Type:        "vcs",
+				VCSRepoPath: repoPath,
+				VCSBranch:   branch,
+			})
+		}
+	}
+
+	return sessions, nil
+}
+
+// workUnits returns the git branches of repoPath, or, for jj-colocated repos
+// (identified by a .jj directory), its jj bookmarks instead.
+func (s vcsSource) workUnits(repoPath string) []string {
+	if info, err := os.Stat(filepath.Join(repoPath, ".jj")); err == nil && info.IsDir() {
+		return gitClient.Bookmarks(repoPath)
+	}
+	return gitClient.Branches(repoPath)
+}
+
+func (s vcsSource) worktreePath(repo, branch string) string {
+	template := s.config.WorktreeDir
+	if template == "" {
+		template = filepath.Join(s.config.Root, "{repo}", ".worktrees", "{branch}")
+	}
+
+	template = strings.ReplaceAll(template, "{repo}", repo)
+	template = strings.ReplaceAll(template, "{branch}", branch)
+
+	path, err := expandHomePath(template)
+	if err != nil {
+		return template
+	}
+	return path
+}
+
+// ensureVCSWorktree materializes a vcs-sourced session's worktree the first
+// time it's connected to, via `git worktree add`.
+func ensureVCSWorktree(session Session) error {
+	if _, err := os.Stat(session.Path); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(session.Path), 0o755); err != nil {
+		return fmt.Errorf("error preparing worktree directory: %v", err)
+	}
+
+	if err := gitClient.AddWorktree(session.VCSRepoPath, session.Path, session.VCSBranch); err != nil {
+		return fmt.Errorf("error creating git worktree for %s: %v", session.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorktreeSource_List(t *testing.T) {
+	fake := &fakeRunner{
+		outputs: map[string][]byte{
+			"git -C /repos/mingle worktree list --porcelain": []byte(
+				"worktree /repos/mingle\nHEAD abc\nbranch refs/heads/main\n\n" +
+					"worktree /repos/mingle-feature\nHEAD def\nbranch refs/heads/feature\n",
+			),
+		},
+	}
+
+	original := gitClient
+	gitClient = newGit(fake)
+	defer func() { gitClient = original }()
+
+	typ := "worktreeroot"
+	source := worktreeSource{sessions: []ConfigSession{{Type: &typ, Path: "/repos/mingle"}}}
+
+	sessions, err := source.List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d: %+v", len(sessions), sessions)
+	}
+	if sessions[0].Path != "/repos/mingle" || sessions[1].Path != "/repos/mingle-feature" {
+		t.Errorf("unexpected worktree paths: %+v", sessions)
+	}
+	for _, s := range sessions {
+		if s.Type != "worktreeroot" {
+			t.Errorf("session %q has Type %q, want worktreeroot", s.Name, s.Type)
+		}
+	}
+}
+
+func TestVCSSource_List_UsesJJBookmarksForJJRepos(t *testing.T) {
+	root := t.TempDir()
+	repoPath := filepath.Join(root, "mingle")
+	if err := os.MkdirAll(filepath.Join(repoPath, ".jj"), 0o755); err != nil {
+		t.Fatalf("failed to set up fake jj repo: %v", err)
+	}
+
+	fake := &fakeRunner{
+		outputs: map[string][]byte{
+			`jj -R ` + repoPath + ` bookmark list -T name ++ "\n"`: []byte("main\nfeature\n"),
+		},
+	}
+
+	original := gitClient
+	gitClient = newGit(fake)
+	defer func() { gitClient = original }()
+
+	source := vcsSource{config: VCSConfig{Root: root}}
+
+	sessions, err := source.List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d: %+v", len(sessions), sessions)
+	}
+	if sessions[0].Name != "mingle/main" || sessions[1].Name != "mingle/feature" {
+		t.Errorf("unexpected session names: %+v", sessions)
+	}
+	for _, c := range fake.calls {
+		if c == "git -C "+repoPath+" branch --format=%(refname:short)" {
+			t.Errorf("expected jj repo to skip git branch listing, got calls %v", fake.calls)
+		}
+	}
+}
+
+func TestVCSSource_List_SoftFailsOnUnreadableRoot(t *testing.T) {
+	source := vcsSource{config: VCSConfig{Root: "/nonexistent/vcs/root"}}
+
+	sessions, err := source.List()
+	if err != nil {
+		t.Fatalf("List returned error %v, want nil so other sources still run", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("expected no sessions, got %+v", sessions)
+	}
+}
+
+func TestMergeSessionSources_VCSErrorDoesNotSuppressOtherSources(t *testing.T) {
+	fake := &fakeRunner{
+		outputs: map[string][]byte{
+			"tmux list-sessions -F #{session_name}": []byte("running-session\n"),
+		},
+	}
+
+	original := tmuxClient
+	tmuxClient = newTmux(fake)
+	defer func() { tmuxClient = original }()
+
+	config := &Config{
+		Sources:  []string{"tmux", "config", "vcs"},
+		Sessions: []ConfigSession{{Path: "/work/project"}},
+		VCS:      &VCSConfig{Root: "/nonexistent/vcs/root"},
+	}
+
+	sessions, err := mergeSessionSources(buildSessionSources(config))
+	if err != nil {
+		t.Fatalf("mergeSessionSources returned error: %v", err)
+	}
+
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions from tmux/config despite a broken vcs source, got %+v", sessions)
+	}
+}
+
+func TestWorktreeSource_SkipsNonWorktreerootEntries(t *testing.T) {
+	fake := &fakeRunner{}
+	original := gitClient
+	gitClient = newGit(fake)
+	defer func() { gitClient = original }()
+
+	source := worktreeSource{sessions: []ConfigSession{{Path: "/plain/path"}}}
+
+	sessions, err := source.List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("expected no sessions, got %+v", sessions)
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("expected no git calls, got %v", fake.calls)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import "strings"
+
+// Tmux wraps the tmux CLI behind a Runner, so session discovery and session
+// lifecycle management can be unit tested against a fake runner instead of a
+// real tmux server.
+type Tmux struct {
+	runner Runner
+}
+
+func newTmux(runner Runner) *Tmux {
+	return &Tmux{runner: runner}
+}
+
+func (t *Tmux) ListSessions() []Session {
+	output, err := t.runner.Run("", "tmux", "list-sessions", "-F", "#{session_name}")
+	if err != nil {
+		return []Session{}
+	}
+
+	var sessions []Session
+	for _, line := range strings.Split(string(output), "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			sessions = append(sessions, Session{Name: trimmed})
+		}
+	}
+
+	return sessions
+}
+
+func (t *Tmux) CurrentSession() (string, error) {
+	output, err := t.runner.Run("", "tmux", "display-message", "-p", "#S")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (t *Tmux) SwitchClient(sessionName string) error {
+	_, err := t.runner.Run("", "tmux", "switch-client", "-t", sessionName)
+	return err
+}
+
+func (t *Tmux) NewSession(name, path string) error {
+	_, err := t.runner.Run("", "tmux", "new-session", "-s", name, "-d", "-c", path)
+	return err
+}
+
+func (t *Tmux) KillSession(name string) error {
+	_, err := t.runner.Run("", "tmux", "kill-session", "-t", name)
+	return err
+}
+
+// Run executes an arbitrary tmux subcommand. It's the primitive
+// createNativeTmuxSession builds window/pane layouts out of (new-window,
+// split-window, send-keys, select-layout, select-window, ...).
+func (t *Tmux) Run(args ...string) error {
+	_, err := t.runner.Run("", "tmux", args...)
+	return err
+}
@@ -0,0 +1,28 @@
+package main
+
+import "strings"
+
+// Zoxide wraps the zoxide CLI behind a Runner.
+type Zoxide struct {
+	runner Runner
+}
+
+func newZoxide(runner Runner) *Zoxide {
+	return &Zoxide{runner: runner}
+}
+
+func (z *Zoxide) Query() []Session {
+	output, err := z.runner.Run("", "zoxide", "query", "-l")
+	if err != nil {
+		return []Session{}
+	}
+
+	var results []Session
+	for _, line := range strings.Split(string(output), "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			results = append(results, Session{Name: trimmed, Path: trimmed})
+		}
+	}
+
+	return results
+}